@@ -0,0 +1,81 @@
+package client
+
+import (
+	"sync"
+	"time"
+
+	"github.com/marloncalvo/cert-manager-webhook-unifi/internal/unifi/types"
+)
+
+const defaultCacheTTL = 30 * time.Second
+
+// recordCache holds the last GetEndpoints response for a site, reused for
+// reads within the TTL and extended on a 304 Not Modified from the
+// controller. It is safe for concurrent use by multiple webhook requests.
+type recordCache struct {
+	mu sync.RWMutex
+
+	records      []types.DNSRecord
+	lastModified string
+	fetchedAt    time.Time
+}
+
+// get returns the cached records and whether they are still within the TTL.
+func (c *recordCache) get(ttl time.Duration) ([]types.DNSRecord, bool) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	if c.fetchedAt.IsZero() || time.Since(c.fetchedAt) >= ttl {
+		return nil, false
+	}
+	return c.records, true
+}
+
+// conditionalHeader returns the Last-Modified value to send as
+// If-Modified-Since, or "" if nothing has been cached yet.
+func (c *recordCache) conditionalHeader() string {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.lastModified
+}
+
+// set stores a fresh record set fetched just now.
+func (c *recordCache) set(records []types.DNSRecord, lastModified string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.records = records
+	c.lastModified = lastModified
+	c.fetchedAt = time.Now()
+}
+
+// extend keeps the cached records but resets the TTL clock, used when the
+// controller confirms nothing changed via a 304 Not Modified. It returns the
+// kept records.
+func (c *recordCache) extend() []types.DNSRecord {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.fetchedAt = time.Now()
+	return c.records
+}
+
+// invalidate drops the cached records, and the Last-Modified value used to
+// make conditional requests, so the next GetEndpoints call always goes to
+// the controller with a full GET instead of risking a 304 against the
+// pre-mutation state. Without clearing lastModified, a mutation landing in
+// the same second as the prior fetch could get a legitimate 304 and extend
+// the stale cache instead of seeing the mutation.
+func (c *recordCache) invalidate() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.records = nil
+	c.fetchedAt = time.Time{}
+	c.lastModified = ""
+}
+
+func (c *httpClient) cacheTTL() time.Duration {
+	if c.Config.CacheTTL > 0 {
+		return c.Config.CacheTTL
+	}
+	return defaultCacheTTL
+}
@@ -0,0 +1,75 @@
+package client
+
+import (
+	"testing"
+	"time"
+
+	"github.com/marloncalvo/cert-manager-webhook-unifi/internal/unifi/types"
+)
+
+func TestRecordCacheGetMiss(t *testing.T) {
+	var c recordCache
+
+	if _, ok := c.get(time.Minute); ok {
+		t.Fatal("expected cache miss before anything is set")
+	}
+}
+
+func TestRecordCacheGetHitWithinTTL(t *testing.T) {
+	var c recordCache
+	want := []types.DNSRecord{{Key: "a.example.com", RecordType: "TXT"}}
+
+	c.set(want, "Mon, 01 Jan 2024 00:00:00 GMT")
+
+	got, ok := c.get(time.Minute)
+	if !ok {
+		t.Fatal("expected cache hit within TTL")
+	}
+	if len(got) != 1 || got[0].Key != want[0].Key {
+		t.Fatalf("got %v, want %v", got, want)
+	}
+}
+
+func TestRecordCacheGetMissAfterTTL(t *testing.T) {
+	var c recordCache
+	c.set([]types.DNSRecord{{Key: "a.example.com"}}, "")
+
+	if _, ok := c.get(0); ok {
+		t.Fatal("expected cache miss once the TTL has elapsed")
+	}
+}
+
+func TestRecordCacheExtendResetsTTLWithoutChangingRecords(t *testing.T) {
+	var c recordCache
+	c.set([]types.DNSRecord{{Key: "a.example.com"}}, "Mon, 01 Jan 2024 00:00:00 GMT")
+
+	// Simulate the clock having moved past the TTL.
+	c.fetchedAt = time.Now().Add(-time.Hour)
+	if _, ok := c.get(time.Minute); ok {
+		t.Fatal("expected cache miss before extend")
+	}
+
+	c.extend()
+
+	got, ok := c.get(time.Minute)
+	if !ok {
+		t.Fatal("expected cache hit after extend")
+	}
+	if len(got) != 1 || got[0].Key != "a.example.com" {
+		t.Fatalf("extend changed the cached records: %v", got)
+	}
+}
+
+func TestRecordCacheInvalidateClearsRecordsAndConditionalHeader(t *testing.T) {
+	var c recordCache
+	c.set([]types.DNSRecord{{Key: "a.example.com"}}, "Mon, 01 Jan 2024 00:00:00 GMT")
+
+	c.invalidate()
+
+	if _, ok := c.get(time.Hour); ok {
+		t.Fatal("expected cache miss after invalidate")
+	}
+	if h := c.conditionalHeader(); h != "" {
+		t.Fatalf("expected invalidate to clear the conditional header, got %q", h)
+	}
+}
@@ -0,0 +1,553 @@
+package client
+
+import (
+	"bytes"
+	"crypto/tls"
+	"encoding/json"
+	"fmt"
+	"golang.org/x/net/publicsuffix"
+	"io"
+	"log"
+	"net"
+	"net/http"
+	"net/http/cookiejar"
+	"time"
+
+	"sigs.k8s.io/external-dns/endpoint"
+
+	"go.uber.org/zap"
+
+	"github.com/marloncalvo/cert-manager-webhook-unifi/internal/unifi/types"
+)
+
+type ClientURLs struct {
+	Login   string
+	Records string
+}
+
+// Client is the surface the provider layer needs from a UniFi controller
+// client, letting future controller flavors (UDM, UDR, self-hosted) plug in
+// behind the same interface. UpdateIn and DeleteIn take an already-fetched
+// record list so a caller applying several changes in one reconcile (see
+// Provider.ApplyChanges) can fetch once up front and reuse it, rather than
+// paying for a GetEndpoints call per mutation.
+type Client interface {
+	GetEndpoints() ([]types.DNSRecord, error)
+	Create(ep *endpoint.Endpoint, records []types.DNSRecord) ([]*types.DNSRecord, error)
+	UpdateIn(old, newEp *endpoint.Endpoint, records []types.DNSRecord) ([]*types.DNSRecord, error)
+	DeleteIn(ep *endpoint.Endpoint, records []types.DNSRecord) error
+}
+
+// httpClient is the DNS provider client.
+type httpClient struct {
+	*Config
+	*http.Client
+	csrf       string
+	ClientURLs *ClientURLs
+	cache      *recordCache
+}
+
+// New creates a Client for the given configuration and logs in to store
+// cookies, unless APIKey is set.
+func New(config *Config) (Client, error) {
+	return newUnifiClient(config)
+}
+
+const (
+	unifiLoginPath          = "%s/api/auth/login"
+	unifiLoginPathExternal  = "%s/api/login"
+	unifiRecordPath         = "%s/proxy/network/v2/api/site/%s/static-dns/%s"
+	unifiRecordPathExternal = "%s/v2/api/site/%s/static-dns/%s"
+)
+
+// newUnifiClient creates a new DNS provider client and logs in to store
+// cookies, unless APIKey is set.
+func newUnifiClient(config *Config) (*httpClient, error) {
+	jar, err := cookiejar.New(&cookiejar.Options{PublicSuffixList: publicsuffix.List})
+	if err != nil {
+		return nil, err
+	}
+
+	// Create the HTTP client
+	client := &httpClient{
+		Config: config,
+		Client: &http.Client{
+			Transport: &http.Transport{
+				TLSClientConfig: &tls.Config{InsecureSkipVerify: config.SkipTLSVerify},
+			},
+			Jar: jar,
+		},
+		ClientURLs: &ClientURLs{
+			Login:   unifiLoginPath,
+			Records: unifiRecordPath,
+		},
+		cache: &recordCache{},
+	}
+
+	if config.ExternalController {
+		client.ClientURLs.Login = unifiLoginPathExternal
+		client.ClientURLs.Records = unifiRecordPathExternal
+	}
+
+	// API-key auth needs no session: there is no login call and no CSRF
+	// token to carry forward.
+	if config.APIKey == "" {
+		if err := client.login(); err != nil {
+			return nil, err
+		}
+	}
+
+	return client, nil
+}
+
+// login performs a login request to the UniFi controller.
+func (c *httpClient) login() error {
+	jsonBody, err := json.Marshal(types.Login{
+		Username: c.Config.User,
+		Password: c.Config.Password,
+		Remember: true,
+	})
+	if err != nil {
+		return err
+	}
+
+	// Perform the login request
+	resp, err := c.doRequest(
+		http.MethodPost,
+		formatUrl(c.ClientURLs.Login, c.Config.Host),
+		jsonBody,
+		nil,
+	)
+	if err != nil {
+		return err
+	}
+
+	defer resp.Body.Close()
+
+	// Check if the login was successful
+	if resp.StatusCode != http.StatusOK {
+		respBody, _ := io.ReadAll(resp.Body)
+		log.Println("login failed", zap.String("status", resp.Status), zap.String("response", string(respBody)))
+		return fmt.Errorf("login failed: %s", resp.Status)
+	}
+
+	// Retrieve CSRF token from the response headers
+	if csrf := resp.Header.Get("x-csrf-token"); csrf != "" {
+		c.csrf = resp.Header.Get("x-csrf-token")
+	}
+	return nil
+}
+
+// doRequest issues an HTTP request, retrying transient failures (401
+// session expiry, 429/502/503/504, and request timeouts) according to the
+// client's retry policy. body is taken as a []byte, rather than an
+// io.Reader, so a retry can resend the exact same payload. extraHeaders are
+// set on the request in addition to the usual auth headers (may be nil).
+// Any status in extraOK is treated as success in addition to 200, e.g. 304
+// Not Modified for a conditional GET.
+func (c *httpClient) doRequest(method, path string, body []byte, extraHeaders map[string]string, extraOK ...int) (*http.Response, error) {
+	var resp *http.Response
+
+	for attempt := 0; ; attempt++ {
+		req, err := http.NewRequest(method, path, bodyReader(body))
+		if err != nil {
+			return nil, err
+		}
+
+		c.setHeaders(req)
+		for k, v := range extraHeaders {
+			req.Header.Set(k, v)
+		}
+
+		resp, err = c.Client.Do(req)
+		if netErr, ok := err.(net.Error); ok && netErr.Timeout() && attempt < c.maxRetries() {
+			observeRequest(method, "timeout")
+			log.Println("request timed out, retrying", zap.String("method", method), zap.Int("attempt", attempt))
+			time.Sleep(c.backoff(attempt, nil))
+			continue
+		}
+		if err != nil {
+			observeRequest(method, "error")
+			return nil, err
+		}
+
+		observeRequest(method, statusLabel(resp.StatusCode))
+
+		if csrf := resp.Header.Get("X-CSRF-Token"); csrf != "" {
+			c.csrf = csrf
+		}
+
+		// Session auth can recover from a 401 by re-logging in; API-key auth
+		// has no session to refresh, so just surface the error instead.
+		if resp.StatusCode == http.StatusUnauthorized && c.Config.APIKey == "" && attempt < c.maxRetries() {
+			log.Println("received 401 unauthorized, attempting to re-login")
+			resp.Body.Close()
+			if err := c.login(); err != nil {
+				log.Println("re-login failed", zap.Error(err))
+				return nil, err
+			}
+			continue
+		}
+
+		if isRetryableStatus(resp.StatusCode) && attempt < c.maxRetries() {
+			delay := c.backoff(attempt, resp)
+			log.Println("transient failure, retrying", zap.Int("status", resp.StatusCode), zap.Int("attempt", attempt), zap.Duration("delay", delay))
+			resp.Body.Close()
+			time.Sleep(delay)
+			continue
+		}
+
+		break
+	}
+
+	if resp.StatusCode == http.StatusOK || containsStatus(extraOK, resp.StatusCode) {
+		return resp, nil
+	}
+
+	// It is unknown at this time if the UniFi API returns anything other than 200 for these types of requests.
+	respBody, bodyErr := io.ReadAll(io.LimitReader(resp.Body, 512))
+	resp.Body.Close()
+	if bodyErr != nil {
+		return nil, bodyErr
+	}
+
+	var apiError types.UnifiErrorResponse
+	if err := json.Unmarshal(respBody, &apiError); err != nil {
+		return nil, fmt.Errorf("failed to decode json: %w", err)
+	}
+
+	return nil, fmt.Errorf("%s request to %s returned %d: %s", method, path, resp.StatusCode, apiError.Message)
+}
+
+func containsStatus(statuses []int, status int) bool {
+	for _, s := range statuses {
+		if s == status {
+			return true
+		}
+	}
+	return false
+}
+
+// bodyReader wraps body in a fresh io.Reader, or returns nil for requests
+// with no body (e.g. GET/DELETE).
+func bodyReader(body []byte) io.Reader {
+	if body == nil {
+		return nil
+	}
+	return bytes.NewReader(body)
+}
+
+// GetEndpoints retrieves the list of DNS records from the UniFi controller,
+// reusing a cached copy within Config.CacheTTL. A cached Last-Modified value
+// is sent as If-Modified-Since so a still-valid cache outside the TTL can be
+// extended with a cheap 304 instead of a full re-fetch.
+func (c *httpClient) GetEndpoints() ([]types.DNSRecord, error) {
+	if cached, ok := c.cache.get(c.cacheTTL()); ok {
+		cacheHitsTotal.Inc()
+		return c.filterRecords(cached), nil
+	}
+
+	headers := map[string]string{}
+	if lastModified := c.cache.conditionalHeader(); lastModified != "" {
+		headers["If-Modified-Since"] = lastModified
+	}
+
+	resp, err := c.doRequest(
+		http.MethodGet,
+		formatUrl(c.ClientURLs.Records, c.Config.Host, c.Config.Site),
+		nil,
+		headers,
+		http.StatusNotModified,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotModified {
+		cacheHitsTotal.Inc()
+		return c.filterRecords(c.cache.extend()), nil
+	}
+
+	var records []types.DNSRecord
+	if err = json.NewDecoder(resp.Body).Decode(&records); err != nil {
+		log.Println("Failed to decode response", zap.Error(err))
+		return nil, err
+	}
+
+	// Loop through records to modify SRV type
+	for i, record := range records {
+		if record.RecordType != "SRV" {
+			continue
+		}
+
+		// Modify the Target for SRV records
+		records[i].Value = fmt.Sprintf("%d %d %d %s",
+			*record.Priority,
+			*record.Weight,
+			*record.Port,
+			record.Value,
+		)
+		records[i].Priority = nil
+		records[i].Weight = nil
+		records[i].Port = nil
+	}
+
+	log.Println("retrieved records", zap.Int("count", len(records)))
+	c.cache.set(records, resp.Header.Get("Last-Modified"))
+
+	return c.filterRecords(records), nil
+}
+
+// Create creates one DNS record per target in the UniFi controller, plus an
+// ownership TXT record when Config.OwnerID is set, and returns the created
+// target records. records is an already-fetched record list, used to check
+// whether an ownership TXT record already exists for ep.DNSName, rather than
+// calling GetEndpoints itself.
+func (c *httpClient) Create(ep *endpoint.Endpoint, records []types.DNSRecord) ([]*types.DNSRecord, error) {
+	createdRecords := make([]*types.DNSRecord, 0, len(ep.Targets))
+
+	for _, target := range ep.Targets {
+		record := types.DNSRecord{
+			Enabled:    true,
+			Key:        ep.DNSName,
+			RecordType: ep.RecordType,
+			TTL:        ep.RecordTTL,
+			Value:      target,
+		}
+
+		if ep.RecordType == "SRV" {
+			record.Priority = new(int)
+			record.Weight = new(int)
+			record.Port = new(int)
+
+			if _, err := fmt.Sscanf(target, "%d %d %d %s", record.Priority, record.Weight, record.Port, &record.Value); err != nil {
+				return nil, err
+			}
+		}
+
+		createdRecord, err := c.createRecord(record)
+		if err != nil {
+			return nil, err
+		}
+
+		createdRecords = append(createdRecords, createdRecord)
+	}
+
+	if err := c.createOwnerRecord(records, ep.DNSName); err != nil {
+		return nil, err
+	}
+
+	return createdRecords, nil
+}
+
+// createRecord POSTs a single static-dns record and returns the record as
+// created by the controller.
+func (c *httpClient) createRecord(record types.DNSRecord) (*types.DNSRecord, error) {
+	jsonBody, err := json.Marshal(record)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := c.doRequest(
+		http.MethodPost,
+		formatUrl(c.ClientURLs.Records, c.Config.Host, c.Config.Site),
+		jsonBody,
+		nil,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	c.cache.invalidate()
+
+	var createdRecord types.DNSRecord
+	if err := json.NewDecoder(resp.Body).Decode(&createdRecord); err != nil {
+		return nil, err
+	}
+
+	return &createdRecord, nil
+}
+
+// DeleteIn deletes every DNS record backing the endpoint's targets,
+// resolving them against an already-fetched record list rather than calling
+// GetEndpoints itself.
+func (c *httpClient) DeleteIn(ep *endpoint.Endpoint, records []types.DNSRecord) error {
+	if !c.isOwned(records, ep.DNSName) {
+		return fmt.Errorf("refusing to delete %s: not owned by %q", ep.DNSName, c.Config.OwnerID)
+	}
+
+	lookups, err := c.lookupIdentifierIn(records, ep.DNSName, ep.RecordType, ep.Targets)
+	if err != nil {
+		return err
+	}
+
+	deleted := make(map[string]struct{}, len(lookups))
+	for _, lookup := range lookups {
+		if err := c.deleteRecord(lookup.ID); err != nil {
+			return err
+		}
+		deleted[lookup.ID] = struct{}{}
+	}
+
+	// If nothing else is left under this key, the ownership TXT record has
+	// nothing left to guard and would otherwise be orphaned forever.
+	if !hasOtherRecords(records, ep.DNSName, deleted) {
+		if err := c.deleteOwnerRecord(records, ep.DNSName); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// hasOtherRecords reports whether records contains a record for key, other
+// than its ownership TXT record and anything in excludeIDs, so callers can
+// tell whether a key is about to have no records left.
+func hasOtherRecords(records []types.DNSRecord, key string, excludeIDs map[string]struct{}) bool {
+	for _, r := range records {
+		if r.Key != key {
+			continue
+		}
+		if _, excluded := excludeIDs[r.ID]; excluded {
+			continue
+		}
+		return true
+	}
+	return false
+}
+
+// deleteRecord DELETEs a single static-dns record by ID.
+func (c *httpClient) deleteRecord(id string) error {
+	resp, err := c.doRequest(
+		http.MethodDelete,
+		formatUrl(c.ClientURLs.Records, c.Config.Host, c.Config.Site, id),
+		nil,
+		nil,
+	)
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+	c.cache.invalidate()
+	return nil
+}
+
+// UpdateIn updates, in place, the records shared between old and newEp's
+// targets, preserving their record ID and enabled state, resolving them
+// against an already-fetched record list rather than calling GetEndpoints
+// itself. Targets added or removed between old and newEp are not handled
+// here; callers should pair this with Create/DeleteIn for those.
+func (c *httpClient) UpdateIn(old, newEp *endpoint.Endpoint, records []types.DNSRecord) ([]*types.DNSRecord, error) {
+	if !c.isOwned(records, old.DNSName) {
+		return nil, fmt.Errorf("refusing to update %s: not owned by %q", old.DNSName, c.Config.OwnerID)
+	}
+
+	oldTargets := make(map[string]struct{}, len(old.Targets))
+	for _, t := range old.Targets {
+		oldTargets[t] = struct{}{}
+	}
+
+	var updatedRecords []*types.DNSRecord
+
+	for _, target := range newEp.Targets {
+		if _, unchanged := oldTargets[target]; !unchanged {
+			continue
+		}
+
+		existing, err := c.lookupIdentifierIn(records, old.DNSName, old.RecordType, []string{target})
+		if err != nil {
+			return nil, err
+		}
+
+		record := types.DNSRecord{
+			ID:         existing[0].ID,
+			Enabled:    existing[0].Enabled,
+			Key:        newEp.DNSName,
+			RecordType: newEp.RecordType,
+			TTL:        newEp.RecordTTL,
+			Value:      target,
+		}
+
+		if newEp.RecordType == "SRV" {
+			record.Priority = new(int)
+			record.Weight = new(int)
+			record.Port = new(int)
+
+			if _, err := fmt.Sscanf(target, "%d %d %d %s", record.Priority, record.Weight, record.Port, &record.Value); err != nil {
+				return nil, err
+			}
+		}
+
+		jsonBody, err := json.Marshal(record)
+		if err != nil {
+			return nil, err
+		}
+
+		resp, err := c.doRequest(
+			http.MethodPut,
+			formatUrl(c.ClientURLs.Records, c.Config.Host, c.Config.Site, record.ID),
+			jsonBody,
+			nil,
+		)
+		if err != nil {
+			return nil, err
+		}
+		c.cache.invalidate()
+
+		var updatedRecord types.DNSRecord
+		err = json.NewDecoder(resp.Body).Decode(&updatedRecord)
+		resp.Body.Close()
+		if err != nil {
+			return nil, err
+		}
+
+		updatedRecords = append(updatedRecords, &updatedRecord)
+	}
+
+	return updatedRecords, nil
+}
+
+// lookupIdentifierIn finds the DNS records matching key, recordType and, if
+// given, one of recordValue within an already-fetched record list. All
+// matches are returned so callers can act on the full set of records
+// backing a multi-target endpoint.
+func (c *httpClient) lookupIdentifierIn(records []types.DNSRecord, key, recordType string, recordValue []string) ([]*types.DNSRecord, error) {
+	log.Println("Looking up identifier", zap.String("key", key), zap.String("recordType", recordType))
+
+	var matches []*types.DNSRecord
+
+	if len(recordValue) == 0 {
+		for i, r := range records {
+			if r.Key == key && r.RecordType == recordType {
+				matches = append(matches, &records[i])
+			}
+		}
+	} else {
+		for _, value := range recordValue {
+			for i, r := range records {
+				if r.Key == key && r.RecordType == recordType && r.Value == value {
+					matches = append(matches, &records[i])
+				}
+			}
+		}
+	}
+
+	if len(matches) == 0 {
+		return nil, fmt.Errorf("record not found: %s", key)
+	}
+
+	return matches, nil
+}
+
+// setHeaders sets the headers for the HTTP request.
+func (c *httpClient) setHeaders(req *http.Request) {
+	if c.Config.APIKey != "" {
+		req.Header.Set("X-API-KEY", c.Config.APIKey)
+	} else {
+		// Add the saved CSRF header.
+		req.Header.Set("X-CSRF-Token", c.csrf)
+	}
+	req.Header.Add("Accept", "application/json")
+	req.Header.Add("Content-Type", "application/json; charset=utf-8")
+}
@@ -0,0 +1,164 @@
+package client
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"sigs.k8s.io/external-dns/endpoint"
+
+	"github.com/marloncalvo/cert-manager-webhook-unifi/internal/unifi/types"
+)
+
+const testLastModified = "Mon, 01 Jan 2024 00:00:00 GMT"
+
+// newTestClient builds an httpClient pointed at server with API-key auth, so
+// construction doesn't also need to simulate a session login.
+func newTestClient(t *testing.T, server *httptest.Server, cacheTTL time.Duration) *httpClient {
+	t.Helper()
+
+	c, err := newUnifiClient(&Config{
+		Host:     server.URL,
+		Site:     "default",
+		APIKey:   "test-api-key",
+		CacheTTL: cacheTTL,
+	})
+	if err != nil {
+		t.Fatalf("newUnifiClient: %v", err)
+	}
+	return c
+}
+
+func TestGetEndpointsCachesWithinTTL(t *testing.T) {
+	var requests int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&requests, 1)
+		w.Header().Set("Last-Modified", testLastModified)
+		json.NewEncoder(w).Encode([]types.DNSRecord{{Key: "a.example.com", RecordType: "TXT", Value: "v1"}})
+	}))
+	defer server.Close()
+
+	c := newTestClient(t, server, time.Minute)
+
+	if _, err := c.GetEndpoints(); err != nil {
+		t.Fatalf("first GetEndpoints: %v", err)
+	}
+	if _, err := c.GetEndpoints(); err != nil {
+		t.Fatalf("second GetEndpoints: %v", err)
+	}
+
+	if got := atomic.LoadInt32(&requests); got != 1 {
+		t.Fatalf("expected the second call to be served from cache, got %d requests", got)
+	}
+}
+
+func TestGetEndpointsRevalidatesViaConditionalGet(t *testing.T) {
+	var requests int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&requests, 1)
+		if r.Header.Get("If-Modified-Since") == testLastModified {
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		w.Header().Set("Last-Modified", testLastModified)
+		json.NewEncoder(w).Encode([]types.DNSRecord{{Key: "a.example.com", RecordType: "TXT", Value: "v1"}})
+	}))
+	defer server.Close()
+
+	// A near-zero TTL forces every call past the cache.get() check, so the
+	// second call takes the conditional-GET path instead of a plain hit.
+	c := newTestClient(t, server, time.Nanosecond)
+
+	first, err := c.GetEndpoints()
+	if err != nil {
+		t.Fatalf("first GetEndpoints: %v", err)
+	}
+
+	second, err := c.GetEndpoints()
+	if err != nil {
+		t.Fatalf("second GetEndpoints: %v", err)
+	}
+
+	if got := atomic.LoadInt32(&requests); got != 2 {
+		t.Fatalf("expected a conditional GET on the second call, got %d requests", got)
+	}
+	if len(second) != len(first) || second[0].Value != first[0].Value {
+		t.Fatalf("expected the 304 response to return the previously cached records, got %v", second)
+	}
+}
+
+func TestDoRequestRetriesTransientFailures(t *testing.T) {
+	var requests int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&requests, 1) <= 2 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.Header().Set("Last-Modified", testLastModified)
+		json.NewEncoder(w).Encode([]types.DNSRecord{{Key: "a.example.com", RecordType: "TXT", Value: "v1"}})
+	}))
+	defer server.Close()
+
+	c := newTestClient(t, server, time.Minute)
+	c.Config.RetryBaseDelay = time.Millisecond
+	c.Config.RetryMaxDelay = 5 * time.Millisecond
+
+	records, err := c.GetEndpoints()
+	if err != nil {
+		t.Fatalf("GetEndpoints: %v", err)
+	}
+	if len(records) != 1 {
+		t.Fatalf("got %d records, want 1", len(records))
+	}
+	if got := atomic.LoadInt32(&requests); got != 3 {
+		t.Fatalf("expected 2 failed attempts plus 1 success, got %d requests", got)
+	}
+}
+
+func TestCreateOwnerRecordIsIdempotent(t *testing.T) {
+	var created []types.DNSRecord
+	var postsToOwnerKey int32
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodGet:
+			w.Header().Set("Last-Modified", testLastModified)
+			json.NewEncoder(w).Encode(created)
+		case http.MethodPost:
+			var record types.DNSRecord
+			json.NewDecoder(r.Body).Decode(&record)
+			record.ID = "new-id"
+			if record.Key == ownerRecordKey("a.example.com") {
+				atomic.AddInt32(&postsToOwnerKey, 1)
+			}
+			created = append(created, record)
+			json.NewEncoder(w).Encode(record)
+		}
+	}))
+	defer server.Close()
+
+	c := newTestClient(t, server, time.Nanosecond)
+	c.Config.OwnerID = "cluster-a"
+
+	ep := &endpoint.Endpoint{DNSName: "a.example.com", RecordType: "TXT", Targets: endpoint.Targets{"v1"}}
+	if _, err := c.Create(ep, nil); err != nil {
+		t.Fatalf("first Create: %v", err)
+	}
+
+	// A second reconcile re-fetches the record list, as Provider.ApplyChanges
+	// does, and should see the owner record the first Create just made.
+	records, err := c.GetEndpoints()
+	if err != nil {
+		t.Fatalf("GetEndpoints: %v", err)
+	}
+	if _, err := c.Create(ep, records); err != nil {
+		t.Fatalf("second Create: %v", err)
+	}
+
+	if got := atomic.LoadInt32(&postsToOwnerKey); got != 1 {
+		t.Fatalf("expected exactly one owner TXT record to be created, got %d", got)
+	}
+}
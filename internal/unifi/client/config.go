@@ -0,0 +1,52 @@
+package client
+
+import "time"
+
+// Config holds the settings needed to talk to a UniFi controller.
+type Config struct {
+	Host     string
+	Site     string
+	User     string
+	Password string
+
+	// APIKey, when set, authenticates requests with an X-API-KEY header
+	// instead of a session cookie, skipping login and CSRF handling
+	// entirely. Supported on UniFi OS controllers running Network 9+.
+	APIKey string
+
+	// SkipTLSVerify disables TLS certificate verification, which is commonly
+	// needed for controllers using the default self-signed certificate.
+	SkipTLSVerify bool
+
+	// ExternalController indicates the controller is not a UniFi OS console
+	// (e.g. a self-hosted Network application), which uses a different URL
+	// scheme for login and records.
+	ExternalController bool
+
+	// MaxRetries, RetryBaseDelay and RetryMaxDelay tune the retry/backoff
+	// policy doRequest applies to transient failures (429/502/503/504 and
+	// request timeouts). Zero values fall back to package defaults.
+	MaxRetries     int
+	RetryBaseDelay time.Duration
+	RetryMaxDelay  time.Duration
+
+	// DomainFilter and ExcludeDomains scope GetEndpoints to the domains this
+	// webhook instance is allowed to manage, so it doesn't see (and later
+	// delete) static DNS entries created by hand or by another tool sharing
+	// the controller. ExcludeDomains takes precedence over DomainFilter.
+	DomainFilter   []string
+	ExcludeDomains []string
+
+	// OwnerID, when set, enables the TXT ownership registry: every record
+	// this client creates gets a sibling "_owner.<key>" TXT record, and
+	// Update/Delete refuse to touch records that aren't marked as owned by
+	// this OwnerID. Leaving it empty disables the registry entirely, so
+	// existing deployments are unaffected.
+	OwnerID string
+
+	// CacheTTL controls how long GetEndpoints results are served from the
+	// cache before the controller is asked again (with If-Modified-Since when
+	// it sent a Last-Modified header); a zero value falls back to a package
+	// default.
+	CacheTTL time.Duration
+}
@@ -0,0 +1,39 @@
+package client
+
+import (
+	"strings"
+
+	"github.com/marloncalvo/cert-manager-webhook-unifi/internal/unifi/types"
+)
+
+// filterRecords scopes records to Config.DomainFilter, excluding anything
+// matched by Config.ExcludeDomains.
+func (c *httpClient) filterRecords(records []types.DNSRecord) []types.DNSRecord {
+	if len(c.Config.DomainFilter) == 0 && len(c.Config.ExcludeDomains) == 0 {
+		return records
+	}
+
+	filtered := make([]types.DNSRecord, 0, len(records))
+	for _, r := range records {
+		if matchesDomain(r.Key, c.Config.ExcludeDomains) {
+			continue
+		}
+		if len(c.Config.DomainFilter) > 0 && !matchesDomain(r.Key, c.Config.DomainFilter) {
+			continue
+		}
+		filtered = append(filtered, r)
+	}
+
+	return filtered
+}
+
+// matchesDomain reports whether name is one of domains, or a subdomain of
+// one of them.
+func matchesDomain(name string, domains []string) bool {
+	for _, domain := range domains {
+		if name == domain || strings.HasSuffix(name, "."+domain) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,61 @@
+package client
+
+import (
+	"testing"
+
+	"github.com/marloncalvo/cert-manager-webhook-unifi/internal/unifi/types"
+)
+
+func TestMatchesDomain(t *testing.T) {
+	domains := []string{"example.com"}
+
+	cases := map[string]bool{
+		"example.com":      true,
+		"sub.example.com":  true,
+		"notexample.com":   false,
+		"example.com.evil": false,
+		"other.org":        false,
+	}
+
+	for name, want := range cases {
+		if got := matchesDomain(name, domains); got != want {
+			t.Errorf("matchesDomain(%q, %v) = %v, want %v", name, domains, got, want)
+		}
+	}
+}
+
+func TestFilterRecordsNoFilterConfigured(t *testing.T) {
+	c := &httpClient{Config: &Config{}}
+	records := []types.DNSRecord{{Key: "a.example.com"}, {Key: "b.other.org"}}
+
+	got := c.filterRecords(records)
+	if len(got) != len(records) {
+		t.Fatalf("got %d records, want %d with no filter configured", len(got), len(records))
+	}
+}
+
+func TestFilterRecordsDomainFilter(t *testing.T) {
+	c := &httpClient{Config: &Config{DomainFilter: []string{"example.com"}}}
+	records := []types.DNSRecord{{Key: "a.example.com"}, {Key: "b.other.org"}}
+
+	got := c.filterRecords(records)
+	if len(got) != 1 || got[0].Key != "a.example.com" {
+		t.Fatalf("got %v, want only a.example.com", got)
+	}
+}
+
+func TestFilterRecordsExcludeDomainsTakesPrecedence(t *testing.T) {
+	c := &httpClient{Config: &Config{
+		DomainFilter:   []string{"example.com"},
+		ExcludeDomains: []string{"internal.example.com"},
+	}}
+	records := []types.DNSRecord{
+		{Key: "a.example.com"},
+		{Key: "svc.internal.example.com"},
+	}
+
+	got := c.filterRecords(records)
+	if len(got) != 1 || got[0].Key != "a.example.com" {
+		t.Fatalf("got %v, want internal.example.com subdomain excluded", got)
+	}
+}
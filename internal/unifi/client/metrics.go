@@ -0,0 +1,31 @@
+package client
+
+import (
+	"strconv"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+var (
+	cacheHitsTotal = promauto.NewCounter(prometheus.CounterOpts{
+		Name: "unifi_cache_hits_total",
+		Help: "Number of GetEndpoints calls served from the in-memory record cache, including 304 Not Modified extensions.",
+	})
+
+	apiRequestsTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "unifi_api_requests_total",
+		Help: "Number of HTTP requests issued to the UniFi controller, by method and response status.",
+	}, []string{"method", "status"})
+)
+
+// observeRequest records an attempted HTTP call for the api-requests metric.
+// status is either an HTTP status code or a short label such as "timeout"
+// for requests that never got a response.
+func observeRequest(method, status string) {
+	apiRequestsTotal.WithLabelValues(method, status).Inc()
+}
+
+func statusLabel(code int) string {
+	return strconv.Itoa(code)
+}
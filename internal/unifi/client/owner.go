@@ -0,0 +1,84 @@
+package client
+
+import (
+	"fmt"
+
+	"github.com/marloncalvo/cert-manager-webhook-unifi/internal/unifi/types"
+)
+
+const ownerHeritage = "cert-manager-webhook-unifi"
+
+// ownerRecordKey is the static-dns key of the TXT record tracking who owns
+// key.
+func ownerRecordKey(key string) string {
+	return "_owner." + key
+}
+
+// ownerRecordValue is the external-dns-style heritage value written into an
+// ownership TXT record.
+func ownerRecordValue(ownerID string) string {
+	return fmt.Sprintf("heritage=%s,owner=%s", ownerHeritage, ownerID)
+}
+
+// isOwned reports whether records contains an ownership TXT record for key
+// matching Config.OwnerID. The registry is disabled when OwnerID is unset,
+// so unconfigured deployments keep today's behavior.
+func (c *httpClient) isOwned(records []types.DNSRecord, key string) bool {
+	if c.Config.OwnerID == "" {
+		return true
+	}
+
+	return c.findOwnerRecord(records, key) != nil
+}
+
+// findOwnerRecord returns the ownership TXT record for key, matching
+// Config.OwnerID, or nil if none exists yet.
+func (c *httpClient) findOwnerRecord(records []types.DNSRecord, key string) *types.DNSRecord {
+	want := ownerRecordValue(c.Config.OwnerID)
+	for i, r := range records {
+		if r.RecordType == "TXT" && r.Key == ownerRecordKey(key) && r.Value == want {
+			return &records[i]
+		}
+	}
+	return nil
+}
+
+// createOwnerRecord creates the ownership TXT record for key, if the
+// registry is enabled and no such record exists yet in records, an
+// already-fetched record list. Without this check, every Create call would
+// append a fresh duplicate ownership record instead of reusing the one
+// already guarding key; checking against records rather than calling
+// GetEndpoints also avoids a guaranteed-to-miss fetch, since createRecord
+// just invalidated the cache creating the target record(s) above.
+func (c *httpClient) createOwnerRecord(records []types.DNSRecord, key string) error {
+	if c.Config.OwnerID == "" {
+		return nil
+	}
+	if c.findOwnerRecord(records, key) != nil {
+		return nil
+	}
+
+	_, err := c.createRecord(types.DNSRecord{
+		Enabled:    true,
+		Key:        ownerRecordKey(key),
+		RecordType: "TXT",
+		Value:      ownerRecordValue(c.Config.OwnerID),
+	})
+	return err
+}
+
+// deleteOwnerRecord deletes the ownership TXT record for key, if the
+// registry is enabled and a record exists, so it isn't left behind once
+// nothing else under key remains to guard.
+func (c *httpClient) deleteOwnerRecord(records []types.DNSRecord, key string) error {
+	if c.Config.OwnerID == "" {
+		return nil
+	}
+
+	owner := c.findOwnerRecord(records, key)
+	if owner == nil {
+		return nil
+	}
+
+	return c.deleteRecord(owner.ID)
+}
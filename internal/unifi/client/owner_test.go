@@ -0,0 +1,52 @@
+package client
+
+import (
+	"testing"
+
+	"github.com/marloncalvo/cert-manager-webhook-unifi/internal/unifi/types"
+)
+
+func TestIsOwnedDisabledWithoutOwnerID(t *testing.T) {
+	c := &httpClient{Config: &Config{}}
+
+	if !c.isOwned(nil, "a.example.com") {
+		t.Fatal("expected isOwned to always be true when OwnerID is unset")
+	}
+}
+
+func TestIsOwnedRequiresMatchingOwnerRecord(t *testing.T) {
+	c := &httpClient{Config: &Config{OwnerID: "cluster-a"}}
+	records := []types.DNSRecord{
+		{Key: "_owner.a.example.com", RecordType: "TXT", Value: ownerRecordValue("cluster-a")},
+	}
+
+	if !c.isOwned(records, "a.example.com") {
+		t.Fatal("expected a.example.com to be owned by cluster-a")
+	}
+	if c.isOwned(records, "b.example.com") {
+		t.Fatal("expected b.example.com, with no owner record, to not be owned")
+	}
+
+	other := &httpClient{Config: &Config{OwnerID: "cluster-b"}}
+	if other.isOwned(records, "a.example.com") {
+		t.Fatal("expected cluster-b to not own a record created by cluster-a")
+	}
+}
+
+func TestHasOtherRecords(t *testing.T) {
+	records := []types.DNSRecord{
+		{ID: "1", Key: "a.example.com"},
+		{ID: "2", Key: "a.example.com"},
+		{ID: "3", Key: "b.example.com"},
+	}
+
+	if hasOtherRecords(records, "a.example.com", map[string]struct{}{"1": {}, "2": {}}) {
+		t.Fatal("expected no other records once both a.example.com records are excluded")
+	}
+	if !hasOtherRecords(records, "a.example.com", map[string]struct{}{"1": {}}) {
+		t.Fatal("expected record 2 to still count as another record")
+	}
+	if hasOtherRecords(records, "missing.example.com", nil) {
+		t.Fatal("expected no records for a key with no entries")
+	}
+}
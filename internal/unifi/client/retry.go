@@ -0,0 +1,68 @@
+package client
+
+import (
+	"math/rand"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+const (
+	defaultMaxRetries     = 3
+	defaultRetryBaseDelay = 250 * time.Millisecond
+	defaultRetryMaxDelay  = 5 * time.Second
+)
+
+// maxRetries returns the configured retry budget, falling back to a sane
+// default when unset.
+func (c *httpClient) maxRetries() int {
+	if c.Config.MaxRetries > 0 {
+		return c.Config.MaxRetries
+	}
+	return defaultMaxRetries
+}
+
+func (c *httpClient) retryBaseDelay() time.Duration {
+	if c.Config.RetryBaseDelay > 0 {
+		return c.Config.RetryBaseDelay
+	}
+	return defaultRetryBaseDelay
+}
+
+func (c *httpClient) retryMaxDelay() time.Duration {
+	if c.Config.RetryMaxDelay > 0 {
+		return c.Config.RetryMaxDelay
+	}
+	return defaultRetryMaxDelay
+}
+
+// isRetryableStatus reports whether statusCode represents a transient failure
+// worth retrying, such as a restarting controller or a rate limit.
+func isRetryableStatus(statusCode int) bool {
+	switch statusCode {
+	case http.StatusTooManyRequests, http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return true
+	default:
+		return false
+	}
+}
+
+// backoff computes the exponential delay (with full jitter) before the
+// given retry attempt, honoring a Retry-After response header when set.
+func (c *httpClient) backoff(attempt int, resp *http.Response) time.Duration {
+	if resp != nil {
+		if after := resp.Header.Get("Retry-After"); after != "" {
+			if seconds, err := strconv.Atoi(after); err == nil {
+				return time.Duration(seconds) * time.Second
+			}
+		}
+	}
+
+	max := c.retryMaxDelay()
+	delay := c.retryBaseDelay() * time.Duration(1<<uint(attempt))
+	if delay > max || delay <= 0 {
+		delay = max
+	}
+
+	return time.Duration(rand.Int63n(int64(delay) + 1))
+}
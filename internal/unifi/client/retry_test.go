@@ -0,0 +1,54 @@
+package client
+
+import (
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestIsRetryableStatus(t *testing.T) {
+	retryable := []int{http.StatusTooManyRequests, http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout}
+	for _, status := range retryable {
+		if !isRetryableStatus(status) {
+			t.Errorf("expected %d to be retryable", status)
+		}
+	}
+
+	notRetryable := []int{http.StatusOK, http.StatusBadRequest, http.StatusUnauthorized, http.StatusNotFound}
+	for _, status := range notRetryable {
+		if isRetryableStatus(status) {
+			t.Errorf("expected %d to not be retryable", status)
+		}
+	}
+}
+
+func TestBackoffHonorsRetryAfter(t *testing.T) {
+	c := &httpClient{Config: &Config{}}
+	resp := &http.Response{Header: http.Header{"Retry-After": []string{"2"}}}
+
+	if got := c.backoff(0, resp); got != 2*time.Second {
+		t.Fatalf("got %v, want 2s", got)
+	}
+}
+
+func TestBackoffIsBoundedByRetryMaxDelay(t *testing.T) {
+	c := &httpClient{Config: &Config{RetryBaseDelay: time.Second, RetryMaxDelay: 3 * time.Second}}
+
+	for attempt := 0; attempt < 10; attempt++ {
+		if got := c.backoff(attempt, nil); got > 3*time.Second {
+			t.Fatalf("attempt %d: got %v, want <= 3s", attempt, got)
+		}
+	}
+}
+
+func TestMaxRetriesFallsBackToDefault(t *testing.T) {
+	c := &httpClient{Config: &Config{}}
+	if got := c.maxRetries(); got != defaultMaxRetries {
+		t.Fatalf("got %d, want %d", got, defaultMaxRetries)
+	}
+
+	c.Config.MaxRetries = 7
+	if got := c.maxRetries(); got != 7 {
+		t.Fatalf("got %d, want 7", got)
+	}
+}
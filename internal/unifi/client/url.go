@@ -0,0 +1,14 @@
+package client
+
+import "fmt"
+
+// formatUrl substitutes host and any additional path segments into a URL
+// template containing the corresponding number of "%s" verbs.
+func formatUrl(template, host string, args ...string) string {
+	params := make([]interface{}, 0, len(args)+1)
+	params = append(params, host)
+	for _, a := range args {
+		params = append(params, a)
+	}
+	return fmt.Sprintf(template, params...)
+}
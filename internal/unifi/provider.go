@@ -0,0 +1,92 @@
+package unifi
+
+import (
+	"context"
+
+	"sigs.k8s.io/external-dns/endpoint"
+	"sigs.k8s.io/external-dns/plan"
+
+	"github.com/marloncalvo/cert-manager-webhook-unifi/internal/unifi/client"
+)
+
+// Provider is the external-dns provider backed by a UniFi controller.
+type Provider struct {
+	client client.Client
+}
+
+// NewProvider creates a Provider and logs in to the configured controller,
+// unless an APIKey is configured.
+func NewProvider(config *client.Config) (*Provider, error) {
+	c, err := client.New(config)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Provider{client: c}, nil
+}
+
+// ApplyChanges creates, updates and deletes DNS records on the UniFi
+// controller to match the given plan, in Create -> UpdateNew -> Delete
+// order. It fetches the current record list once up front and threads it
+// into every Update/Delete below, rather than each call re-fetching it, so
+// a reconcile with several changes costs one GetEndpoints round-trip.
+func (p *Provider) ApplyChanges(ctx context.Context, changes *plan.Changes) error {
+	records, err := p.client.GetEndpoints()
+	if err != nil {
+		return err
+	}
+
+	for _, ep := range changes.Create {
+		if _, err := p.client.Create(ep, records); err != nil {
+			return err
+		}
+	}
+
+	for i, new := range changes.UpdateNew {
+		old := changes.UpdateOld[i]
+
+		if _, err := p.client.UpdateIn(old, new, records); err != nil {
+			return err
+		}
+
+		if added := diffTargets(new, old); len(added.Targets) > 0 {
+			if _, err := p.client.Create(added, records); err != nil {
+				return err
+			}
+		}
+
+		if removed := diffTargets(old, new); len(removed.Targets) > 0 {
+			if err := p.client.DeleteIn(removed, records); err != nil {
+				return err
+			}
+		}
+	}
+
+	for _, ep := range changes.Delete {
+		if err := p.client.DeleteIn(ep, records); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// diffTargets returns a copy of a scoped to the targets that do not also
+// appear in b, so callers can Create/Delete just the targets that actually
+// changed between an endpoint's old and new state.
+func diffTargets(a, b *endpoint.Endpoint) *endpoint.Endpoint {
+	bTargets := make(map[string]struct{}, len(b.Targets))
+	for _, t := range b.Targets {
+		bTargets[t] = struct{}{}
+	}
+
+	diff := *a
+	diff.Targets = nil
+	for _, t := range a.Targets {
+		if _, ok := bTargets[t]; !ok {
+			diff.Targets = append(diff.Targets, t)
+		}
+	}
+
+	return &diff
+}
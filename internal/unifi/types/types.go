@@ -0,0 +1,30 @@
+package types
+
+import (
+	"sigs.k8s.io/external-dns/endpoint"
+)
+
+// DNSRecord represents a single static-dns entry on the UniFi controller.
+type DNSRecord struct {
+	ID         string       `json:"_id,omitempty"`
+	Enabled    bool         `json:"enabled"`
+	Key        string       `json:"key"`
+	RecordType string       `json:"record_type"`
+	TTL        endpoint.TTL `json:"ttl,omitempty"`
+	Value      string       `json:"value"`
+	Priority   *int         `json:"priority,omitempty"`
+	Weight     *int         `json:"weight,omitempty"`
+	Port       *int         `json:"port,omitempty"`
+}
+
+// Login is the request body sent to the UniFi auth endpoint.
+type Login struct {
+	Username string `json:"username"`
+	Password string `json:"password"`
+	Remember bool   `json:"remember"`
+}
+
+// UnifiErrorResponse models the error envelope returned by the UniFi API.
+type UnifiErrorResponse struct {
+	Message string `json:"message"`
+}